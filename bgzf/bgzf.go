@@ -0,0 +1,333 @@
+// Package bgzf implements reading of BGZF (Blocked GNU Zip Format) files,
+// the block-compressed gzip variant produced by `bgzip` and used throughout
+// genomics tooling (BAM, tabix, samtools faidx .gzi companions, etc). Unlike
+// plain gzip, a BGZF stream is a concatenation of independently-compressed
+// blocks, which allows random access via VirtualOffset and lets this package
+// decompress blocks concurrently while still returning them in order.
+package bgzf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// bgzfMagic is the fixed gzip header prefix (ID1 ID2 CM FLG) shared by every
+// BGZF block: gzip magic with the FEXTRA flag set.
+var bgzfMagic = []byte{0x1f, 0x8b, 0x08, 0x04}
+
+// bgzfEOF is the 28-byte empty BGZF block that every well-formed BGZF file
+// ends with.
+var bgzfEOF = []byte{
+	0x1f, 0x8b, 0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x06, 0x00,
+	0x42, 0x43, 0x02, 0x00, 0x1b, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00,
+}
+
+const fixedHeaderSize = 12 // ID1 ID2 CM FLG MTIME(4) XFL OS XLEN(2)
+
+// VirtualOffset addresses a position within a BGZF stream as a compressed
+// block offset paired with an offset into that block's decompressed data,
+// packed as coffset<<16 | uoffset.
+type VirtualOffset uint64
+
+// NewVirtualOffset packs a compressed block offset and an uncompressed
+// in-block offset into a VirtualOffset.
+func NewVirtualOffset(coffset int64, uoffset uint16) VirtualOffset {
+	return VirtualOffset(uint64(coffset)<<16 | uint64(uoffset))
+}
+
+// Compressed returns the compressed byte offset of the block containing
+// this position.
+func (v VirtualOffset) Compressed() int64 {
+	return int64(v >> 16)
+}
+
+// Uncompressed returns the offset into the block's decompressed data.
+func (v VirtualOffset) Uncompressed() uint16 {
+	return uint16(v & 0xffff)
+}
+
+func (v VirtualOffset) String() string {
+	return fmt.Sprintf("%d:%d", v.Compressed(), v.Uncompressed())
+}
+
+// IsBGZF reports whether header, the first bytes of a gzip stream, begins a
+// BGZF block, i.e. a gzip header carrying a "BC" extra subfield.
+func IsBGZF(header []byte) bool {
+	if len(header) < 4 || !bytes.Equal(header[:4], bgzfMagic) {
+		return false
+	}
+	if len(header) < fixedHeaderSize {
+		return false
+	}
+	xlen := int(binary.LittleEndian.Uint16(header[10:12]))
+	if len(header) < fixedHeaderSize+xlen {
+		return false
+	}
+	_, err := bcBlockSize(header[fixedHeaderSize : fixedHeaderSize+xlen])
+	return err == nil
+}
+
+// bcBlockSize scans a gzip extra field for the two-byte "BC" subfield and
+// returns the total size (in bytes) of the BGZF block it describes.
+func bcBlockSize(extra []byte) (int, error) {
+	for i := 0; i+4 <= len(extra); {
+		si1, si2 := extra[i], extra[i+1]
+		slen := int(binary.LittleEndian.Uint16(extra[i+2 : i+4]))
+		if si1 == 'B' && si2 == 'C' && slen == 2 {
+			bsize := int(binary.LittleEndian.Uint16(extra[i+4 : i+6]))
+			return bsize + 1, nil
+		}
+		i += 4 + slen
+	}
+	return 0, fmt.Errorf("bgzf: missing BC extra subfield")
+}
+
+type job struct {
+	coffset int64
+	raw     []byte
+	result  chan blockResult
+}
+
+type blockResult struct {
+	coffset int64
+	data    []byte
+	err     error
+}
+
+// Reader decodes a BGZF stream from src, decompressing blocks concurrently
+// across a small pool of workers while preserving block order, and supports
+// VirtualOffset-based seeking.
+type Reader struct {
+	src     io.ReadSeeker
+	workers int
+
+	jobs    chan job
+	outputs chan chan blockResult
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+
+	cur        []byte
+	curCoffset int64
+	curOffset  int // offset into cur; an int since a block can decompress to the full 65536-byte BGZF maximum, which overflows uint16
+	err        error
+}
+
+// NewReader returns a Reader decoding the BGZF stream from src, decompressing
+// blocks across workers goroutines concurrently (at least 1).
+func NewReader(src io.ReadSeeker, workers int) *Reader {
+	if workers < 1 {
+		workers = 1
+	}
+	r := &Reader{src: src, workers: workers}
+	r.start()
+	return r
+}
+
+func (r *Reader) start() {
+	r.jobs = make(chan job, r.workers)
+	r.outputs = make(chan chan blockResult, r.workers)
+	r.closeCh = make(chan struct{})
+
+	r.wg.Add(r.workers + 1)
+	for i := 0; i < r.workers; i++ {
+		go func() {
+			defer r.wg.Done()
+			r.worker()
+		}()
+	}
+	go func() {
+		defer r.wg.Done()
+		r.feed()
+		close(r.jobs)
+	}()
+}
+
+// stop signals the feed and worker goroutines to exit and blocks until they
+// have, so that start can safely reallocate jobs/outputs/closeCh without
+// racing the previous generation of goroutines.
+func (r *Reader) stop() {
+	close(r.closeCh)
+	for range r.outputs {
+		// drain so feed/workers can observe closeCh and exit
+	}
+	r.wg.Wait()
+}
+
+func (r *Reader) worker() {
+	for j := range r.jobs {
+		data, err := inflateBlock(j.raw)
+		j.result <- blockResult{coffset: j.coffset, data: data, err: err}
+	}
+}
+
+// feed reads raw compressed blocks from src in order and dispatches them to
+// workers, handing back one result channel per block (in order) so Read can
+// reassemble the decompressed stream in the original block sequence.
+func (r *Reader) feed() {
+	defer close(r.outputs)
+	for {
+		coffset, err := r.src.Seek(0, io.SeekCurrent)
+		if err != nil {
+			r.emit(blockResult{err: err})
+			return
+		}
+
+		head := make([]byte, fixedHeaderSize)
+		n, err := io.ReadFull(r.src, head)
+		if n == 0 && err == io.EOF {
+			return
+		}
+		if err != nil {
+			r.emit(blockResult{err: err})
+			return
+		}
+		if !bytes.Equal(head[:4], bgzfMagic) {
+			r.emit(blockResult{err: fmt.Errorf("bgzf: not a BGZF block at offset %d", coffset)})
+			return
+		}
+
+		xlen := int(binary.LittleEndian.Uint16(head[10:12]))
+		extra := make([]byte, xlen)
+		if _, err := io.ReadFull(r.src, extra); err != nil {
+			r.emit(blockResult{err: err})
+			return
+		}
+		bsize, err := bcBlockSize(extra)
+		if err != nil {
+			r.emit(blockResult{err: err})
+			return
+		}
+
+		rest := make([]byte, bsize-fixedHeaderSize-xlen)
+		if _, err := io.ReadFull(r.src, rest); err != nil {
+			r.emit(blockResult{err: err})
+			return
+		}
+
+		raw := make([]byte, 0, bsize)
+		raw = append(raw, head...)
+		raw = append(raw, extra...)
+		raw = append(raw, rest...)
+		if bytes.Equal(raw, bgzfEOF) {
+			return
+		}
+
+		j := job{coffset: coffset, raw: raw, result: make(chan blockResult, 1)}
+		select {
+		case r.jobs <- j:
+		case <-r.closeCh:
+			return
+		}
+		select {
+		case r.outputs <- j.result:
+		case <-r.closeCh:
+			return
+		}
+	}
+}
+
+func (r *Reader) emit(res blockResult) {
+	ch := make(chan blockResult, 1)
+	ch <- res
+	select {
+	case r.outputs <- ch:
+	case <-r.closeCh:
+	}
+}
+
+func inflateBlock(raw []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// Read implements io.Reader, decompressing BGZF blocks in order.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	total := 0
+	for total < len(p) {
+		if r.curOffset >= len(r.cur) {
+			if !r.fill() {
+				if total > 0 {
+					return total, nil
+				}
+				return 0, r.err
+			}
+		}
+		n := copy(p[total:], r.cur[r.curOffset:])
+		r.curOffset += n
+		total += n
+	}
+	return total, nil
+}
+
+// fill advances to the next decompressed block, returning false at EOF or on
+// error (stored in r.err).
+func (r *Reader) fill() bool {
+	ch, ok := <-r.outputs
+	if !ok {
+		r.err = io.EOF
+		return false
+	}
+	res := <-ch
+	if res.err != nil {
+		r.err = res.err
+		return false
+	}
+	r.curCoffset = res.coffset
+	r.cur = res.data
+	r.curOffset = 0
+	return true
+}
+
+// VirtualOffset returns the current read position as a VirtualOffset.
+func (r *Reader) VirtualOffset() VirtualOffset {
+	return NewVirtualOffset(r.curCoffset, uint16(r.curOffset))
+}
+
+// SeekVirtual repositions the reader to v, restarting block decompression
+// from v's compressed offset and skipping to its uncompressed offset within
+// that block.
+func (r *Reader) SeekVirtual(v VirtualOffset) error {
+	r.stop()
+	if _, err := r.src.Seek(v.Compressed(), io.SeekStart); err != nil {
+		return err
+	}
+	r.err = nil
+	r.cur = nil
+	r.curCoffset = v.Compressed()
+	r.curOffset = 0
+	r.start()
+
+	if v.Uncompressed() == 0 {
+		return nil
+	}
+	if !r.fill() {
+		if r.err == io.EOF {
+			return nil
+		}
+		return r.err
+	}
+	if int(v.Uncompressed()) > len(r.cur) {
+		return fmt.Errorf("bgzf: uncompressed offset %d exceeds block size %d", v.Uncompressed(), len(r.cur))
+	}
+	r.curOffset = int(v.Uncompressed())
+	return nil
+}
+
+// Close stops background decompression workers. It does not close the
+// underlying src.
+func (r *Reader) Close() error {
+	r.stop()
+	return nil
+}
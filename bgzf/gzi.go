@@ -0,0 +1,54 @@
+package bgzf
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+type gziEntry struct {
+	Compressed   int64
+	Uncompressed int64
+}
+
+// GZIIndex maps uncompressed byte offsets to BGZF VirtualOffsets, as recorded
+// in a .gzi sidecar file produced by `bgzip -i`.
+type GZIIndex struct {
+	entries []gziEntry // sorted by Uncompressed; entries[0] is the implicit 0,0 start
+}
+
+// ReadGZI parses a .gzi sidecar index.
+func ReadGZI(r io.Reader) (*GZIIndex, error) {
+	var count uint64
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return nil, err
+	}
+
+	idx := &GZIIndex{entries: make([]gziEntry, 1, count+1)}
+	for i := uint64(0); i < count; i++ {
+		var c, u uint64
+		if err := binary.Read(r, binary.LittleEndian, &c); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &u); err != nil {
+			return nil, err
+		}
+		idx.entries = append(idx.entries, gziEntry{Compressed: int64(c), Uncompressed: int64(u)})
+	}
+	return idx, nil
+}
+
+// VirtualOffset returns the VirtualOffset addressing uncompressed byte offset
+// uoff: the BGZF block containing it, plus uoff's offset within that block.
+func (idx *GZIIndex) VirtualOffset(uoff int64) VirtualOffset {
+	lo, hi := 0, len(idx.entries)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if idx.entries[mid].Uncompressed <= uoff {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	e := idx.entries[lo]
+	return NewVirtualOffset(e.Compressed, uint16(uoff-e.Uncompressed))
+}
@@ -0,0 +1,113 @@
+// Command orfinder scans fasta/fastq input for open reading frames (ORFs)
+// across all six reading frames and writes the translated protein
+// sequences to a fasta output file. It will open many input files at once
+// (up to the number of CPU cores), mirroring the fan-out structure of the
+// fq2fa tool.
+//
+//    USAGE: orfinder [options] file1.fasta file2.fastq ...
+//
+// Options:
+//
+//    -min int
+//          minimum ORF length in amino acids (default 25)
+//    -table table
+//          NCBI genetic code table to translate with (default 1)
+//    -o filename
+//          output `filename` (default "orfs.fasta")
+//
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/pbnjay/goseq"
+	"github.com/pbnjay/goseq/translate"
+)
+
+type foundORF struct {
+	Identifier string
+	Amino      string
+}
+
+func scanFiles(wg *sync.WaitGroup, fnChan chan string, orfChan chan foundORF, minAA, table int) {
+	defer wg.Done()
+	for fn := range fnChan {
+		rdr, err := goseq.Open(fn)
+		if err != nil {
+			panic(err)
+		}
+		for rdr.Next() {
+			id := rdr.Identifier()
+			seq := []byte(rdr.Sequence())
+			for i, o := range translate.ORFs(seq, minAA, table) {
+				orfChan <- foundORF{
+					Identifier: fmt.Sprintf("%s_orf%d_frame%d_%d-%d", id, i+1, o.Frame, o.Start, o.End),
+					Amino:      string(o.Amino),
+				}
+			}
+		}
+		if err := rdr.Err(); err != nil && err != io.EOF {
+			panic(err)
+		}
+	}
+}
+
+func main() {
+	outputname := flag.String("o", "orfs.fasta", "output `filename`")
+	minAA := flag.Int("min", 25, "minimum ORF length in amino acids")
+	table := flag.Int("table", 1, "NCBI genetic code `table` to translate with")
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "no input files provided")
+		os.Exit(1)
+	}
+
+	w, err := goseq.Create(*outputname)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	nc := runtime.NumCPU()
+	if len(files) < nc {
+		nc = len(files)
+	}
+
+	fnChan := make(chan string)
+	orfChan := make(chan foundORF)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(nc)
+	for i := 0; i < nc; i++ {
+		go scanFiles(wg, fnChan, orfChan, *minAA, *table)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for o := range orfChan {
+			if err := w.WriteFasta(o.Identifier, o.Amino); err != nil {
+				log.Fatal(err)
+			}
+		}
+		close(done)
+	}()
+
+	for _, fn := range files {
+		fnChan <- fn
+	}
+	close(fnChan)
+	wg.Wait()
+	close(orfChan)
+	<-done
+
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -10,25 +10,55 @@ import (
 	"strings"
 )
 
+// QualityReader is implemented by Readers that expose per-record quality
+// scores. Currently only fastq input supports this; fasta has no quality
+// data. Quality should be called (if at all) after Sequence and before the
+// next call to Next.
+type QualityReader interface {
+	Reader
+
+	// Quality returns the quality string for the current sequence record.
+	Quality() string
+}
+
 type fastFastqReader struct {
-	f  *os.File
-	r  io.Reader
-	br *bufio.Reader
+	f      *os.File
+	r      io.Reader
+	br     *bufio.Reader
+	closer io.Closer // non-nil when r wraps a resource beyond f that needs an explicit Close, e.g. a BGZF reader's background workers
+	closed bool
 
 	identifierBytes []byte
 	lastSeqLen      int
+	qualityRead     bool
 	lastBytes       []byte
 	lastErr         error
 }
 
 func fastqOpenFrom(f *fastFastaReader) (Reader, error) {
 	fq := &fastFastqReader{
-		f: f.f, r: f.r, br: f.br,
+		f: f.f, r: f.r, br: f.br, closer: f.closer,
 		lastErr: f.lastErr,
 	}
 	return fq, nil
 }
 
+// Close releases f and, if set, closer. It is safe to call more than once.
+func (f *fastFastqReader) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	var closeErr error
+	if f.closer != nil {
+		closeErr = f.closer.Close()
+	}
+	if err := f.f.Close(); err != nil && closeErr == nil {
+		closeErr = err
+	}
+	return closeErr
+}
+
 func OpenFastq(filename string) (Reader, error) {
 	ff, err := os.Open(filename)
 	if err != nil {
@@ -77,7 +107,7 @@ func (f *fastFastqReader) Next() bool {
 		f.identifierBytes, f.lastErr = f.br.ReadBytes('\n')
 	}
 	if f.lastErr == io.EOF {
-		f.f.Close()
+		f.Close()
 		return false
 	}
 	if f.lastErr != nil {
@@ -103,6 +133,14 @@ func (f *fastFastqReader) Next() bool {
 		for len(f.identifierBytes) > 0 && f.identifierBytes[0] != '@' && f.lastErr == nil {
 			f.identifierBytes, f.lastErr = f.br.ReadBytes('\n')
 		}
+	} else if f.qualityRead {
+		// Quality() already consumed the previous record's quality block,
+		// so the stream is already positioned at the next identifier line.
+		f.identifierBytes, f.lastErr = f.br.ReadBytes('\n')
+		f.qualityRead = false
+	}
+	if len(f.identifierBytes) == 0 && f.lastErr == io.EOF {
+		f.Close()
 	}
 
 	if len(f.identifierBytes) == 0 {
@@ -145,6 +183,30 @@ func (f *fastFastqReader) Sequence() string {
 	}
 }
 
+// Quality returns the quality string for the current sequence record. It
+// must be called after Sequence and before the next call to Next.
+func (f *fastFastqReader) Quality() string {
+	qual := make([]byte, 0, f.lastSeqLen)
+	for len(qual) < f.lastSeqLen && f.lastErr == nil {
+		f.lastBytes, f.lastErr = f.br.ReadSlice('\n')
+		if f.lastErr == bufio.ErrBufferFull {
+			f.lastErr = nil
+		}
+		if len(f.lastBytes) == 0 {
+			break
+		}
+
+		end := len(f.lastBytes)
+		if f.lastBytes[end-1] == '\n' {
+			end--
+		}
+		qual = append(qual, f.lastBytes[:end]...)
+	}
+	f.lastSeqLen = 0
+	f.qualityRead = true
+	return string(qual)
+}
+
 func (f *fastFastqReader) SequenceBytes(eachbyte func(byte)) error {
 	for {
 		f.lastBytes, f.lastErr = f.br.ReadSlice('\n')
@@ -0,0 +1,396 @@
+package goseq
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pbnjay/goseq/bgzf"
+)
+
+// faiRecord holds the byte offset, sequence length, and line geometry for a
+// single record in a samtools-style .fai index.
+type faiRecord struct {
+	Length    int64
+	Offset    int64
+	LineBases int64
+	LineWidth int64
+}
+
+// IndexedReader provides random access to the sequences of a fasta file via
+// a samtools-compatible .fai index.
+type IndexedReader interface {
+	// Fetch returns the subsequence of name from start to end (0-based,
+	// end-exclusive). end <= 0 means "to the end of the sequence".
+	Fetch(name string, start, end int) (string, error)
+
+	// FetchBytes calls fn for every byte of the requested subsequence,
+	// without allocating the full sequence.
+	FetchBytes(name string, start, end int, fn func(byte)) error
+
+	// Names returns the identifiers of every indexed sequence, in file order.
+	Names() []string
+
+	// Length returns the sequence length of name, or -1 if name is not indexed.
+	Length(name string) int
+
+	// Close closes the underlying file.
+	Close() error
+}
+
+type fastaIndexedReader struct {
+	f       *os.File
+	names   []string
+	records map[string]faiRecord
+}
+
+// OpenIndexed opens filename for random access, loading its adjacent .fai
+// index if one exists or building and writing one otherwise. Gzipped input
+// is only supported when a BGZF .gzi companion index is also present.
+func OpenIndexed(filename string) (IndexedReader, error) {
+	if strings.HasSuffix(filename, ".gz") {
+		return openIndexedBGZF(filename)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	faiName := filename + ".fai"
+	names, records, err := loadFai(faiName)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			f.Close()
+			return nil, err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+		names, records, err = buildFai(f, faiName)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return &fastaIndexedReader{f: f, names: names, records: records}, nil
+}
+
+func openIndexedBGZF(filename string) (IndexedReader, error) {
+	gziFile, err := os.Open(filename + ".gzi")
+	if err != nil {
+		return nil, fmt.Errorf("goseq: %s is gzipped; indexed access requires a BGZF .gzi companion", filename)
+	}
+	defer gziFile.Close()
+	idx, err := bgzf.ReadGZI(gziFile)
+	if err != nil {
+		return nil, fmt.Errorf("goseq: reading %s.gzi: %v", filename, err)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 18)
+	n, _ := io.ReadFull(f, header)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if n != len(header) || !bgzf.IsBGZF(header) {
+		f.Close()
+		return nil, fmt.Errorf("goseq: %s is not BGZF-compressed", filename)
+	}
+
+	workers := runtime.NumCPU()
+	faiName := filename + ".fai"
+	names, records, err := loadFai(faiName)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			f.Close()
+			return nil, err
+		}
+		br := bgzf.NewReader(f, workers)
+		names, records, err = buildFai(br, faiName)
+		br.Close()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return &bgzfIndexedReader{f: f, idx: idx, names: names, records: records, workers: workers}, nil
+}
+
+func loadFai(faiName string) ([]string, map[string]faiRecord, error) {
+	f, err := os.Open(faiName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var names []string
+	records := map[string]faiRecord{}
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Split(sc.Text(), "\t")
+		if len(fields) != 5 {
+			return nil, nil, fmt.Errorf("goseq: malformed .fai line %q", sc.Text())
+		}
+		rec := faiRecord{}
+		for i, dst := range []*int64{&rec.Length, &rec.Offset, &rec.LineBases, &rec.LineWidth} {
+			v, err := strconv.ParseInt(fields[i+1], 10, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("goseq: malformed .fai line %q: %v", sc.Text(), err)
+			}
+			*dst = v
+		}
+		names = append(names, fields[0])
+		records[fields[0]] = rec
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+	return names, records, nil
+}
+
+// buildFai streams through r once from its current position, recording each
+// record's byte offset, sequence length, bases-per-line, and bytes-per-line,
+// then writes the result to faiName. Records with variable-width lines are
+// rejected, as samtools also refuses to index them.
+func buildFai(r io.Reader, faiName string) ([]string, map[string]faiRecord, error) {
+	br := bufio.NewReader(r)
+
+	var names []string
+	records := map[string]faiRecord{}
+
+	var name string
+	var rec faiRecord
+	var sawShortLine, haveRecord bool
+	var pos int64
+
+	flush := func() {
+		if haveRecord {
+			records[name] = rec
+			names = append(names, name)
+		}
+	}
+
+	for {
+		line, rerr := br.ReadBytes('\n')
+		lineLen := int64(len(line))
+		trimmed := strings.TrimRight(string(line), "\r\n")
+
+		switch {
+		case len(trimmed) > 0 && trimmed[0] == '>':
+			fields := strings.Fields(trimmed[1:])
+			if len(fields) == 0 {
+				return nil, nil, fmt.Errorf("goseq: header line %q has no identifier, cannot build .fai index", trimmed)
+			}
+			flush()
+			name = fields[0]
+			rec = faiRecord{Offset: pos + lineLen}
+			sawShortLine, haveRecord = false, true
+
+		case haveRecord && len(trimmed) > 0:
+			lb := int64(len(trimmed))
+			switch {
+			case rec.LineBases == 0:
+				rec.LineBases, rec.LineWidth = lb, lineLen
+			case sawShortLine:
+				return nil, nil, fmt.Errorf("goseq: %s has variable-width lines, cannot build .fai index", name)
+			case lb == rec.LineBases:
+				// normal full line
+			case lb < rec.LineBases:
+				sawShortLine = true
+			default:
+				return nil, nil, fmt.Errorf("goseq: %s has variable-width lines, cannot build .fai index", name)
+			}
+			rec.Length += lb
+		}
+
+		pos += lineLen
+		if rerr != nil {
+			break
+		}
+	}
+	flush()
+
+	if err := writeFai(faiName, names, records); err != nil {
+		return nil, nil, err
+	}
+	return names, records, nil
+}
+
+func writeFai(faiName string, names []string, records map[string]faiRecord) error {
+	f, err := os.Create(faiName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bw := bufio.NewWriter(f)
+	for _, name := range names {
+		r := records[name]
+		fmt.Fprintf(bw, "%s\t%d\t%d\t%d\t%d\n", name, r.Length, r.Offset, r.LineBases, r.LineWidth)
+	}
+	return bw.Flush()
+}
+
+func (r *fastaIndexedReader) Names() []string {
+	return r.names
+}
+
+func (r *fastaIndexedReader) Length(name string) int {
+	rec, ok := r.records[name]
+	if !ok {
+		return -1
+	}
+	return int(rec.Length)
+}
+
+func (r *fastaIndexedReader) Close() error {
+	return r.f.Close()
+}
+
+func (r *fastaIndexedReader) Fetch(name string, start, end int) (string, error) {
+	var out []byte
+	err := r.FetchBytes(name, start, end, func(b byte) {
+		out = append(out, b)
+	})
+	return string(out), err
+}
+
+func (r *fastaIndexedReader) FetchBytes(name string, start, end int, fn func(byte)) error {
+	rec, ok := r.records[name]
+	if !ok {
+		return fmt.Errorf("goseq: unknown sequence %q", name)
+	}
+	start, end, ok = clampRange(rec, start, end)
+	if !ok {
+		return nil
+	}
+
+	startByte := faiStartByte(rec, start)
+	lastBase := int64(end - 1)
+	endByte := rec.Offset + lastBase/rec.LineBases*rec.LineWidth + lastBase%rec.LineBases + 1
+
+	sr := io.NewSectionReader(r.f, startByte, endByte-startByte)
+	return readWrapped(bufio.NewReader(sr), rec, start, end, fn)
+}
+
+// clampRange normalizes start/end against rec's sequence length. The second
+// return value is false if the resulting range is empty.
+func clampRange(rec faiRecord, start, end int) (int, int, bool) {
+	if end <= 0 || int64(end) > rec.Length {
+		end = int(rec.Length)
+	}
+	if start < 0 {
+		start = 0
+	}
+	return start, end, start < end
+}
+
+// faiStartByte computes the byte offset of base start within rec's record.
+func faiStartByte(rec faiRecord, start int) int64 {
+	startBase := int64(start)
+	return rec.Offset + startBase/rec.LineBases*rec.LineWidth + startBase%rec.LineBases
+}
+
+// readWrapped reads the [start,end) bases of rec from r, which must already
+// be positioned at the first base, calling fn for each sequence byte and
+// skipping the line-wrap bytes between lines.
+func readWrapped(r io.Reader, rec faiRecord, start, end int, fn func(byte)) error {
+	remaining := end - start
+	linePos := int64(start) % rec.LineBases
+	skip := rec.LineWidth - rec.LineBases
+	for remaining > 0 {
+		toRead := rec.LineBases - linePos
+		if int64(remaining) < toRead {
+			toRead = int64(remaining)
+		}
+		buf := make([]byte, toRead)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		for _, b := range buf {
+			fn(b)
+		}
+		remaining -= int(toRead)
+		linePos = 0
+		if remaining > 0 && skip > 0 {
+			if _, err := io.CopyN(io.Discard, r, skip); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//////////////////////
+
+// bgzfIndexedReader provides indexed access to a BGZF-compressed
+// (`bgzip`-compressed) fasta file using a .fai index (recorded in
+// uncompressed coordinates, as samtools does) plus a .gzi sidecar mapping
+// those coordinates to BGZF virtual offsets.
+type bgzfIndexedReader struct {
+	f       *os.File
+	idx     *bgzf.GZIIndex
+	names   []string
+	records map[string]faiRecord
+	workers int
+}
+
+func (r *bgzfIndexedReader) Names() []string {
+	return r.names
+}
+
+func (r *bgzfIndexedReader) Length(name string) int {
+	rec, ok := r.records[name]
+	if !ok {
+		return -1
+	}
+	return int(rec.Length)
+}
+
+func (r *bgzfIndexedReader) Close() error {
+	return r.f.Close()
+}
+
+func (r *bgzfIndexedReader) Fetch(name string, start, end int) (string, error) {
+	var out []byte
+	err := r.FetchBytes(name, start, end, func(b byte) {
+		out = append(out, b)
+	})
+	return string(out), err
+}
+
+func (r *bgzfIndexedReader) FetchBytes(name string, start, end int, fn func(byte)) error {
+	rec, ok := r.records[name]
+	if !ok {
+		return fmt.Errorf("goseq: unknown sequence %q", name)
+	}
+	start, end, ok = clampRange(rec, start, end)
+	if !ok {
+		return nil
+	}
+
+	br := bgzf.NewReader(r.f, r.workers)
+	defer br.Close()
+	if err := br.SeekVirtual(r.idx.VirtualOffset(faiStartByte(rec, start))); err != nil {
+		return err
+	}
+	return readWrapped(br, rec, start, end, fn)
+}
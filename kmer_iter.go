@@ -0,0 +1,72 @@
+package goseq
+
+// isACGT reports whether c is an upper or lower case A, C, G, or T.
+func isACGT(c byte) bool {
+	switch c {
+	case 'A', 'a', 'C', 'c', 'G', 'g', 'T', 't':
+		return true
+	}
+	return false
+}
+
+// Iter slides a k-sized window across seq, invoking fn with the packed kmer
+// at each valid position. Runs of non-ACGT bytes (N, IUPAC ambiguity codes,
+// etc.) reset the accumulator, and fn is not called again until k consecutive
+// valid bases have been seen.
+func (b *BaseKmer) Iter(seq []byte, fn func(pos int, k Kmer)) {
+	var cur Kmer
+	valid := 0
+	for i, c := range seq {
+		if !isACGT(c) {
+			valid = 0
+			continue
+		}
+		b.AppendBase(&cur, c)
+		valid++
+		if valid >= b.k {
+			fn(i-b.k+1, cur)
+		}
+	}
+}
+
+// EachKmer slides a k-sized window across every sequence read from r,
+// invoking fn for each valid kmer position. It uses r's ByteReader
+// SequenceBytes method when available to avoid allocating the full sequence.
+func (b *BaseKmer) EachKmer(r Reader, fn func(id string, pos int, k Kmer)) {
+	for r.Next() {
+		id := r.Identifier()
+		if br, ok := r.(ByteReader); ok {
+			var cur Kmer
+			valid, pos := 0, 0
+			err := br.SequenceBytes(func(c byte) {
+				if !isACGT(c) {
+					valid = 0
+					pos++
+					return
+				}
+				b.AppendBase(&cur, c)
+				valid++
+				if valid >= b.k {
+					fn(id, pos-b.k+1, cur)
+				}
+				pos++
+			})
+			if err != nil {
+				return
+			}
+			continue
+		}
+		b.Iter([]byte(r.Sequence()), func(pos int, k Kmer) {
+			fn(id, pos, k)
+		})
+	}
+}
+
+// EachCanonicalKmer behaves like EachKmer but yields the canonical
+// (strand-agnostic) form of each kmer, suitable for building frequency
+// tables that don't depend on which strand was sequenced.
+func (b *BaseKmer) EachCanonicalKmer(r Reader, fn func(id string, pos int, k Kmer)) {
+	b.EachKmer(r, func(id string, pos int, k Kmer) {
+		fn(id, pos, b.Canonical(k))
+	})
+}
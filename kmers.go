@@ -64,6 +64,112 @@ func (b *BaseKmer) AppendBase(k *Kmer, nuc byte) {
 	}
 }
 
+// PrependBase shifts the current kmer right by one base (dropping the
+// right-most), then inserts the nucleotide at the left.
+func (b *BaseKmer) PrependBase(k *Kmer, nuc byte) {
+	*k = b.mask & (*k >> 2)
+	var v Kmer
+	switch nuc {
+	case 'A', 'a':
+		// v = 0
+	case 'C', 'c':
+		v = 1
+	case 'G', 'g':
+		v = 2
+	case 'T', 't':
+		v = 3
+	}
+	*k |= v << uint((b.k-1)*2)
+}
+
+// ReverseComplement returns the reverse complement of k, using the classic
+// bit-parallel trick: complement every 2-bit base with XOR 0b11, then swap
+// adjacent bit-pairs, nibbles, bytes, and halves to reverse their order.
+func (b *BaseKmer) ReverseComplement(k Kmer) Kmer {
+	if k == InvalidKmer {
+		return InvalidKmer
+	}
+	x := uint64(k)
+	x = ^x
+	x = (x>>2)&0x3333333333333333 | (x&0x3333333333333333)<<2
+	x = (x>>4)&0x0F0F0F0F0F0F0F0F | (x&0x0F0F0F0F0F0F0F0F)<<4
+	x = (x>>8)&0x00FF00FF00FF00FF | (x&0x00FF00FF00FF00FF)<<8
+	x = (x>>16)&0x0000FFFF0000FFFF | (x&0x0000FFFF0000FFFF)<<16
+	x = x>>32 | x<<32
+	return Kmer(x >> uint(64-b.k*2))
+}
+
+// Canonical returns the lexicographically smaller of k and its reverse
+// complement, giving a strand-agnostic representation of a kmer.
+func (b *BaseKmer) Canonical(k Kmer) Kmer {
+	rc := b.ReverseComplement(k)
+	if rc < k {
+		return rc
+	}
+	return k
+}
+
+// Neighbors enumerates every kmer (including k itself) reachable from k by
+// substituting up to hammingDist bases.
+func (b *BaseKmer) Neighbors(k Kmer, hammingDist int) []Kmer {
+	if k == InvalidKmer {
+		return nil
+	}
+	seen := map[Kmer]bool{}
+
+	var rec func(cur Kmer, pos, remaining int)
+	rec = func(cur Kmer, pos, remaining int) {
+		seen[cur] = true
+		if remaining == 0 {
+			return
+		}
+		for p := pos; p < b.k; p++ {
+			shift := uint((b.k - 1 - p) * 2)
+			orig := (cur >> shift) & 3
+			for v := Kmer(0); v < 4; v++ {
+				if v == orig {
+					continue
+				}
+				next := (cur &^ (3 << shift)) | (v << shift)
+				rec(next, p+1, remaining-1)
+			}
+		}
+	}
+	rec(k, 0, hammingDist)
+
+	out := make([]Kmer, 0, len(seen))
+	for nk := range seen {
+		out = append(out, nk)
+	}
+	return out
+}
+
+// FromString parses a kmer literal such as "acgt" into its packed
+// representation. It returns InvalidKmer if s contains any non-ACGT byte or
+// exceeds MaxKmerSize.
+func FromString(s string) (Kmer, error) {
+	if len(s) > MaxKmerSize {
+		return InvalidKmer, fmt.Errorf("kmer %q is longer than MaxKmerSize %d", s, MaxKmerSize)
+	}
+	var k Kmer
+	for i := 0; i < len(s); i++ {
+		k <<= 2
+		switch s[i] {
+		case 'A', 'a':
+			// k |= 0
+		case 'C', 'c':
+			k |= 1
+		case 'G', 'g':
+			k |= 2
+		case 'T', 't':
+			k |= 3
+		default:
+			return InvalidKmer, fmt.Errorf("invalid base %q in kmer %q", s[i], s)
+		}
+	}
+	return k, nil
+}
+
 // String returns a string representation of the Kmer.
 func (b *BaseKmer) String(k Kmer) string {
 	if k == InvalidKmer {
@@ -0,0 +1,142 @@
+package goseq
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PairReader reads two fastq files in lockstep, as is standard for
+// paired-end sequencing data (R1/R2).
+type PairReader interface {
+	// Next advances both readers to their next record. It returns false if
+	// either reader is exhausted, the records desync, or an error occurs.
+	Next() bool
+
+	// Identifier1/Identifier2 return the identifier for the current record
+	// of the first/second file.
+	Identifier1() string
+	Identifier2() string
+
+	// Sequence1/Sequence2 return the sequence for the current record of the
+	// first/second file.
+	Sequence1() string
+	Sequence2() string
+
+	// Quality1/Quality2 return the quality string for the current record of
+	// the first/second file.
+	Quality1() string
+	Quality2() string
+
+	// Err returns the last error that occurred during reading.
+	Err() error
+
+	// Progress returns the percentage progress through the pair (0.0-100.0),
+	// taken as the minimum of both files' progress.
+	Progress() float64
+}
+
+type pairReader struct {
+	r1, r2 Reader
+	q1, q2 QualityReader
+
+	lastErr error
+}
+
+// OpenPair opens r1 and r2 as paired-end fastq files, returning a PairReader
+// that consumes both in lockstep and verifies that their record identifiers
+// match (allowing a trailing "/1"/"/2" or Illumina " 1:"/" 2:" suffix to
+// differ between the two).
+func OpenPair(r1, r2 string) (PairReader, error) {
+	a, err := Open(r1)
+	if err != nil {
+		return nil, err
+	}
+	b, err := Open(r2)
+	if err != nil {
+		a.Close()
+		return nil, err
+	}
+
+	p := &pairReader{r1: a, r2: b}
+	p.q1, _ = a.(QualityReader)
+	p.q2, _ = b.(QualityReader)
+	return p, nil
+}
+
+func (p *pairReader) Next() bool {
+	ok1 := p.r1.Next()
+	ok2 := p.r2.Next()
+
+	if !ok1 && !ok2 {
+		p.lastErr = firstRealErr(p.r1.Err(), p.r2.Err())
+		return false
+	}
+	if ok1 != ok2 {
+		p.lastErr = fmt.Errorf("goseq: paired read desync: one input ended before the other")
+		return false
+	}
+	if !matchingIdentifiers(p.r1.Identifier(), p.r2.Identifier()) {
+		p.lastErr = fmt.Errorf("goseq: paired read desync: %q vs %q", p.r1.Identifier(), p.r2.Identifier())
+		return false
+	}
+	return true
+}
+
+func firstRealErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil && err != io.EOF {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchingIdentifiers reports whether id1 and id2 name the same read pair,
+// allowing the trailing "/1"/"/2" or Illumina " 1:"/" 2:" suffix to differ.
+func matchingIdentifiers(id1, id2 string) bool {
+	return pairBaseName(id1) == pairBaseName(id2)
+}
+
+func pairBaseName(id string) string {
+	if i := strings.IndexByte(id, ' '); i >= 0 {
+		if rest := id[i+1:]; len(rest) > 1 && (rest[0] == '1' || rest[0] == '2') && rest[1] == ':' {
+			return id[:i]
+		}
+	}
+	if n := len(id); n >= 2 && id[n-2] == '/' && (id[n-1] == '1' || id[n-1] == '2') {
+		return id[:n-2]
+	}
+	return id
+}
+
+func (p *pairReader) Identifier1() string { return p.r1.Identifier() }
+func (p *pairReader) Identifier2() string { return p.r2.Identifier() }
+func (p *pairReader) Sequence1() string   { return p.r1.Sequence() }
+func (p *pairReader) Sequence2() string   { return p.r2.Sequence() }
+
+func (p *pairReader) Quality1() string {
+	if p.q1 == nil {
+		return ""
+	}
+	return p.q1.Quality()
+}
+
+func (p *pairReader) Quality2() string {
+	if p.q2 == nil {
+		return ""
+	}
+	return p.q2.Quality()
+}
+
+func (p *pairReader) Err() error {
+	return p.lastErr
+}
+
+func (p *pairReader) Progress() float64 {
+	p1, p2 := p.r1.Progress(), p.r2.Progress()
+	if p1 < p2 {
+		return p1
+	}
+	return p2
+}
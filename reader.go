@@ -1,5 +1,6 @@
-// Package goseq provides a DNA/Protein sequence reader interface for fasta/fastq
-// files. It can efficiently read from files with minimal allocations.
+// Package goseq provides a DNA/Protein sequence reader and writer interface
+// for fasta/fastq files. It can efficiently read from files with minimal
+// allocations.
 //
 // Example usage to convert a fastq file to fasta on stdout:
 //
@@ -24,7 +25,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pbnjay/goseq/bgzf"
 )
 
 // Reader is a sequence file reader interface for Fasta/Fastq files.
@@ -46,6 +51,13 @@ type Reader interface {
 	// NB especially for compressed files this may not update due to buffering.
 	// If an error occurs, returns -1.0
 	Progress() float64
+
+	// Close releases any resources held by the reader, including background
+	// decompression workers for formats that use them (e.g. BGZF). It is
+	// called automatically once Next reaches EOF, but not when Next stops on
+	// any other error; callers abandoning a Reader before EOF should call it
+	// explicitly.
+	Close() error
 }
 
 // ByteReader provides a high-performance byte-level reader for each sequence
@@ -64,12 +76,30 @@ type fastFastaReader struct {
 	f       *os.File
 	r       io.Reader
 	br      *bufio.Reader
+	closer  io.Closer // non-nil when r wraps a resource beyond f that needs an explicit Close, e.g. a BGZF reader's background workers
 	lastErr error
+	closed  bool
 
 	identifierBytes []byte
 	lastBytes       []byte
 }
 
+// Close releases f and, if set, closer. It is safe to call more than once.
+func (f *fastFastaReader) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	var closeErr error
+	if f.closer != nil {
+		closeErr = f.closer.Close()
+	}
+	if err := f.f.Close(); err != nil && closeErr == nil {
+		closeErr = err
+	}
+	return closeErr
+}
+
 func (f *fastFastaReader) Progress() float64 {
 	info, err := f.f.Stat()
 	if err != nil || info.Size() <= 0 {
@@ -91,16 +121,34 @@ func Open(filename string) (Reader, error) {
 		return nil, err
 	}
 	r := io.Reader(ff)
+	var closer io.Closer
 	if strings.HasSuffix(filename, ".gz") {
-		r, err = gzip.NewReader(ff)
-		if err != nil {
+		header := make([]byte, 18)
+		n, _ := io.ReadFull(ff, header)
+		if _, err := ff.Seek(0, io.SeekStart); err != nil {
 			return nil, err
 		}
+		if n == len(header) && bgzf.IsBGZF(header) {
+			br := bgzf.NewReader(ff, runtime.NumCPU())
+			r, closer = br, br
+		} else {
+			r, err = gzip.NewReader(ff)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 	if strings.HasSuffix(filename, ".bz2") {
 		r = bzip2.NewReader(ff)
 	}
-	f := &fastFastaReader{f: ff, r: r}
+	if strings.HasSuffix(filename, ".zst") {
+		zr, err := zstd.NewReader(ff)
+		if err != nil {
+			return nil, err
+		}
+		r = zr
+	}
+	f := &fastFastaReader{f: ff, r: r, closer: closer}
 	f.br = bufio.NewReader(r)
 	f.lastErr = errNotStarted
 
@@ -119,7 +167,7 @@ func (f *fastFastaReader) Next() bool {
 		f.identifierBytes, f.lastErr = f.br.ReadBytes('\n')
 	}
 	if f.lastErr == io.EOF {
-		f.f.Close()
+		f.Close()
 		return false
 	}
 	if f.lastErr != nil {
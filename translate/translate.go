@@ -0,0 +1,164 @@
+// Package translate provides DNA-to-protein translation across all six
+// reading frames and open reading frame (ORF) extraction.
+package translate
+
+import "strings"
+
+// standardCodonTable is NCBI genetic code table 1 (the Standard table).
+var standardCodonTable = map[string]byte{
+	"TTT": 'F', "TTC": 'F', "TTA": 'L', "TTG": 'L',
+	"CTT": 'L', "CTC": 'L', "CTA": 'L', "CTG": 'L',
+	"ATT": 'I', "ATC": 'I', "ATA": 'I', "ATG": 'M',
+	"GTT": 'V', "GTC": 'V', "GTA": 'V', "GTG": 'V',
+	"TCT": 'S', "TCC": 'S', "TCA": 'S', "TCG": 'S',
+	"CCT": 'P', "CCC": 'P', "CCA": 'P', "CCG": 'P',
+	"ACT": 'T', "ACC": 'T', "ACA": 'T', "ACG": 'T',
+	"GCT": 'A', "GCC": 'A', "GCA": 'A', "GCG": 'A',
+	"TAT": 'Y', "TAC": 'Y', "TAA": '*', "TAG": '*',
+	"CAT": 'H', "CAC": 'H', "CAA": 'Q', "CAG": 'Q',
+	"AAT": 'N', "AAC": 'N', "AAA": 'K', "AAG": 'K',
+	"GAT": 'D', "GAC": 'D', "GAA": 'E', "GAG": 'E',
+	"TGT": 'C', "TGC": 'C', "TGA": '*', "TGG": 'W',
+	"CGT": 'R', "CGC": 'R', "CGA": 'R', "CGG": 'R',
+	"AGT": 'S', "AGC": 'S', "AGA": 'R', "AGG": 'R',
+	"GGT": 'G', "GGC": 'G', "GGA": 'G', "GGG": 'G',
+}
+
+// codonTables holds the NCBI genetic code tables supported by Translate,
+// keyed by table number. Tables are derived from standardCodonTable with
+// only their differing codon assignments overridden.
+var codonTables = map[int]map[string]byte{
+	1:  standardCodonTable,
+	11: standardCodonTable, // Bacterial, Archaeal and Plant Plastid: same assignments as Standard
+	2: overrideTable(standardCodonTable, map[string]byte{ // Vertebrate Mitochondrial
+		"AGA": '*', "AGG": '*', "ATA": 'M', "TGA": 'W',
+	}),
+	4: overrideTable(standardCodonTable, map[string]byte{ // Mold, Protozoan, Coelenterate Mitochondrial
+		"TGA": 'W',
+	}),
+}
+
+func overrideTable(base map[string]byte, overrides map[string]byte) map[string]byte {
+	t := make(map[string]byte, len(base))
+	for k, v := range base {
+		t[k] = v
+	}
+	for k, v := range overrides {
+		t[k] = v
+	}
+	return t
+}
+
+// Translate translates dna in the given reading frame using the given NCBI
+// genetic code table (1, 2, 4, and 11 are supported; unrecognized tables
+// fall back to the Standard table). frame is 1, 2, or 3 for the forward
+// strand, or -1, -2, -3 to translate the reverse complement. The result has
+// one byte per codon: an amino acid, '*' for a stop codon, or 'X' for a
+// codon containing an ambiguous base.
+func Translate(dna []byte, frame int, table int) []byte {
+	codons, ok := codonTables[table]
+	if !ok {
+		codons = standardCodonTable
+	}
+
+	seq := dna
+	offset := frame
+	if frame < 0 {
+		seq = reverseComplement(dna)
+		offset = -frame
+	}
+	if offset < 1 || offset > 3 {
+		offset = 1
+	}
+	if offset-1 > len(seq) {
+		return nil
+	}
+	seq = seq[offset-1:]
+
+	aa := make([]byte, 0, len(seq)/3)
+	for i := 0; i+3 <= len(seq); i += 3 {
+		codon := strings.ToUpper(string(seq[i : i+3]))
+		a, ok := codons[codon]
+		if !ok {
+			a = 'X'
+		}
+		aa = append(aa, a)
+	}
+	return aa
+}
+
+func reverseComplement(dna []byte) []byte {
+	rc := make([]byte, len(dna))
+	for i, b := range dna {
+		var c byte
+		switch b {
+		case 'A', 'a':
+			c = 'T'
+		case 'C', 'c':
+			c = 'G'
+		case 'G', 'g':
+			c = 'C'
+		case 'T', 't':
+			c = 'A'
+		default:
+			c = 'N'
+		}
+		rc[len(dna)-1-i] = c
+	}
+	return rc
+}
+
+// ORF describes a single open reading frame found by ORFs, in nucleotide
+// coordinates relative to the original (forward-strand) dna slice.
+type ORF struct {
+	Frame int    // 1, 2, 3 for the forward strand; -1, -2, -3 for the reverse strand
+	Start int    // 0-based start position of the ORF (inclusive)
+	End   int    // 0-based end position of the ORF (exclusive), after the stop codon if one was found
+	Amino []byte // translated amino acids, from the start codon up to (not including) the stop codon
+}
+
+// ORFs scans all six reading frames of dna for segments with at least minAA
+// amino acids that run from a codon translated as 'M' (ATG, and also ATA under
+// table 2) to the next stop codon, or to the end of the frame when no stop
+// codon follows, translating with the given NCBI genetic code table.
+func ORFs(dna []byte, minAA int, table int) []ORF {
+	var orfs []ORF
+	for _, frame := range [...]int{1, 2, 3, -1, -2, -3} {
+		aa := Translate(dna, frame, table)
+
+		start := -1
+		for i, a := range aa {
+			switch {
+			case start < 0 && a == 'M':
+				start = i
+			case start >= 0 && a == '*':
+				if i-start >= minAA {
+					orfs = append(orfs, newORF(dna, frame, start, i+1, aa[start:i]))
+				}
+				start = -1
+			}
+		}
+		if start >= 0 && len(aa)-start >= minAA {
+			orfs = append(orfs, newORF(dna, frame, start, len(aa), aa[start:]))
+		}
+	}
+	return orfs
+}
+
+// newORF converts a [startAA,endAA) amino acid range of frame's translation
+// into nucleotide coordinates relative to dna.
+func newORF(dna []byte, frame, startAA, endAA int, amino []byte) ORF {
+	offset := frame - 1
+	if frame < 0 {
+		offset = -frame - 1
+	}
+	start := offset + startAA*3
+	end := offset + endAA*3
+	if frame < 0 {
+		start, end = len(dna)-end, len(dna)-start
+	}
+
+	out := make([]byte, len(amino))
+	copy(out, amino)
+	return ORF{Frame: frame, Start: start, End: end, Amino: out}
+}
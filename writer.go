@@ -0,0 +1,218 @@
+package goseq
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Writer is a sequence file writer interface for Fasta/Fastq files.
+type Writer interface {
+	// WriteFasta writes a single fasta record.
+	WriteFasta(id, seq string) error
+
+	// WriteFastq writes a single fastq record.
+	WriteFastq(id, seq, qual string) error
+
+	// SetLineWrap sets the number of bases written per line before wrapping
+	// (0 disables wrapping).
+	SetLineWrap(n int)
+
+	// Close flushes any buffered output and closes the underlying file.
+	Close() error
+}
+
+// Create opens filename for writing and returns a Writer. The output format
+// is selected from the filename's extension (.fa/.fasta for Fasta, .fq/.fastq
+// for Fastq), and the output is transparently compressed if the filename also
+// carries a .gz, .bz2, or .zst suffix. Writing a .bz2 output shells out to a
+// bzip2 binary on $PATH, since compress/bzip2 only implements decoding; the
+// .gz and .zst paths have no such external dependency.
+func Create(filename string) (Writer, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	base := filename
+	var w io.Writer = f
+	var closers []io.Closer
+
+	switch {
+	case strings.HasSuffix(base, ".gz"):
+		base = strings.TrimSuffix(base, ".gz")
+		gw := gzip.NewWriter(f)
+		w = gw
+		closers = append(closers, gw)
+	case strings.HasSuffix(base, ".bz2"):
+		base = strings.TrimSuffix(base, ".bz2")
+		bw, err := newBzip2Writer(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		w = bw
+		closers = append(closers, bw)
+	case strings.HasSuffix(base, ".zst"):
+		base = strings.TrimSuffix(base, ".zst")
+		zw, err := zstd.NewWriter(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		w = zw
+		closers = append(closers, zw)
+	}
+
+	bw := &baseWriter{f: f, closers: closers, bw: bufio.NewWriter(w)}
+
+	switch {
+	case strings.HasSuffix(base, ".fa"), strings.HasSuffix(base, ".fasta"):
+		bw.lineWrap = 80
+		return &fastaWriter{baseWriter: bw}, nil
+	case strings.HasSuffix(base, ".fq"), strings.HasSuffix(base, ".fastq"):
+		return &fastqWriter{baseWriter: bw}, nil
+	}
+
+	closeAll(closers)
+	f.Close()
+	return nil, fmt.Errorf("goseq: unrecognized output format for %q", filename)
+}
+
+type baseWriter struct {
+	f        *os.File
+	closers  []io.Closer
+	bw       *bufio.Writer
+	lineWrap int
+}
+
+func (w *baseWriter) SetLineWrap(n int) {
+	w.lineWrap = n
+}
+
+// writeWrapped writes seq followed by a newline, splitting it across
+// multiple lines of lineWrap bases if line wrapping is enabled.
+func (w *baseWriter) writeWrapped(seq string) error {
+	if w.lineWrap <= 0 {
+		if _, err := w.bw.WriteString(seq); err != nil {
+			return err
+		}
+		return w.bw.WriteByte('\n')
+	}
+	for len(seq) > w.lineWrap {
+		if _, err := w.bw.WriteString(seq[:w.lineWrap]); err != nil {
+			return err
+		}
+		if err := w.bw.WriteByte('\n'); err != nil {
+			return err
+		}
+		seq = seq[w.lineWrap:]
+	}
+	if _, err := w.bw.WriteString(seq); err != nil {
+		return err
+	}
+	return w.bw.WriteByte('\n')
+}
+
+func (w *baseWriter) Close() error {
+	if err := w.bw.Flush(); err != nil {
+		return err
+	}
+	if err := closeAll(w.closers); err != nil {
+		return err
+	}
+	return w.f.Close()
+}
+
+func closeAll(closers []io.Closer) error {
+	for _, c := range closers {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//////////////////////
+
+type fastaWriter struct {
+	*baseWriter
+}
+
+func (w *fastaWriter) WriteFasta(id, seq string) error {
+	if _, err := w.bw.WriteString(">" + id + "\n"); err != nil {
+		return err
+	}
+	return w.writeWrapped(seq)
+}
+
+func (w *fastaWriter) WriteFastq(id, seq, qual string) error {
+	return fmt.Errorf("goseq: cannot write a fastq record to a fasta output")
+}
+
+type fastqWriter struct {
+	*baseWriter
+}
+
+func (w *fastqWriter) WriteFasta(id, seq string) error {
+	return fmt.Errorf("goseq: cannot write a fasta record to a fastq output")
+}
+
+func (w *fastqWriter) WriteFastq(id, seq, qual string) error {
+	if _, err := w.bw.WriteString("@" + id + "\n"); err != nil {
+		return err
+	}
+	if err := w.writeWrapped(seq); err != nil {
+		return err
+	}
+	if _, err := w.bw.WriteString("+\n"); err != nil {
+		return err
+	}
+	return w.writeWrapped(qual)
+}
+
+//////////////////////
+
+// bzip2Writer shims write-side bzip2 compression by piping through an
+// external bzip2 process, since compress/bzip2 only implements decoding.
+type bzip2Writer struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	done  chan error
+}
+
+func newBzip2Writer(w io.Writer) (io.WriteCloser, error) {
+	if _, err := exec.LookPath("bzip2"); err != nil {
+		return nil, fmt.Errorf("goseq: writing .bz2 output requires a bzip2 binary on $PATH: %v", err)
+	}
+
+	cmd := exec.Command("bzip2", "-z", "-c")
+	cmd.Stdout = w
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("goseq: starting bzip2: %v", err)
+	}
+	bw := &bzip2Writer{cmd: cmd, stdin: stdin, done: make(chan error, 1)}
+	go func() { bw.done <- cmd.Wait() }()
+	return bw, nil
+}
+
+func (b *bzip2Writer) Write(p []byte) (int, error) {
+	return b.stdin.Write(p)
+}
+
+func (b *bzip2Writer) Close() error {
+	if err := b.stdin.Close(); err != nil {
+		return err
+	}
+	return <-b.done
+}